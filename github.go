@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GitHub API environment variables
+const (
+	// EnvGitHubToken authenticates the workflow-runs lookup used by
+	// SLACK_NOTIFY_ON rules that need the previous run's conclusion.
+	EnvGitHubToken = "GITHUB_TOKEN"
+	// EnvGitHubAPIURL overrides the GitHub API base URL (GitHub Enterprise
+	// sets this automatically); defaults to https://api.github.com.
+	EnvGitHubAPIURL = "GITHUB_API_URL"
+	// EnvGitHubWorkflowRef identifies the workflow file, e.g.
+	// "owner/repo/.github/workflows/ci.yml@refs/heads/main".
+	EnvGitHubWorkflowRef = "GITHUB_WORKFLOW_REF"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+type workflowRun struct {
+	ID         int64  `json:"id"`
+	Conclusion string `json:"conclusion"`
+}
+
+type workflowRunsResponse struct {
+	WorkflowRuns []workflowRun `json:"workflow_runs"`
+}
+
+// previousRunConclusion returns the conclusion of the most recent
+// completed run of the current workflow on the current branch, excluding
+// the in-progress run identified by runID. Returns "" with no error if it
+// can't be determined (no token, no workflow file, no prior run) so
+// callers can fall back to the "unknown" behavior of the "changed" and
+// "success-after-failure" rules.
+func previousRunConclusion(token, apiURL, repo, workflowRef, ref, runID string) (string, error) {
+	workflowFile := workflowFileName(workflowRef)
+	if token == "" || workflowFile == "" || repo == "" {
+		return "", nil
+	}
+
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+
+	reqURL := fmt.Sprintf("%s/repos/%s/actions/workflows/%s/runs?branch=%s&per_page=5",
+		apiURL, repo, workflowFile, url.QueryEscape(branchName(ref)))
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("listing workflow runs: %s", res.Status)
+	}
+
+	var parsed workflowRunsResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return "", fmt.Errorf("decoding workflow runs: %w", err)
+	}
+
+	currentID, _ := strconv.ParseInt(runID, 10, 64)
+	for _, run := range parsed.WorkflowRuns {
+		if run.ID == currentID {
+			continue
+		}
+		return run.Conclusion, nil
+	}
+
+	return "", nil
+}
+
+// workflowFileName extracts "ci.yml" from a GITHUB_WORKFLOW_REF like
+// "owner/repo/.github/workflows/ci.yml@refs/heads/main".
+func workflowFileName(workflowRef string) string {
+	path, _, _ := strings.Cut(workflowRef, "@")
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-1]
+}
+
+// branchName trims the refs/heads/ prefix GITHUB_REF carries.
+func branchName(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
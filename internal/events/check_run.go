@@ -0,0 +1,27 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type checkRunRenderer struct{}
+
+func init() { register("check_run", checkRunRenderer{}) }
+
+func (checkRunRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	check := mapField(payload, "check_run")
+
+	status := stringField(check, "conclusion")
+	if status == "" {
+		status = stringField(check, "status")
+	}
+
+	text := fmt.Sprintf("*<%s|%s>* %s", stringField(check, "html_url"), stringField(check, "name"), status)
+
+	return &slack.Message{
+		Text:   text,
+		Blocks: []any{&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}}},
+	}, nil
+}
@@ -0,0 +1,60 @@
+// Package events renders a Slack message from a GitHub webhook payload,
+// choosing the shape of the message based on GITHUB_EVENT_NAME.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+// Renderer builds a Slack message for one decoded GitHub webhook payload.
+type Renderer interface {
+	Render(payload map[string]any) (*slack.Message, error)
+}
+
+var registry = map[string]Renderer{}
+
+// register adds r as the Renderer for eventName. Called from each
+// renderer's init(), so registration order doesn't matter.
+func register(eventName string, r Renderer) {
+	registry[eventName] = r
+}
+
+// RenderEvent decodes raw as a GitHub webhook payload and dispatches it to
+// the Renderer registered for eventName, falling back to genericRenderer
+// for event types without a dedicated implementation.
+func RenderEvent(eventName string, raw []byte) (*slack.Message, error) {
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("decoding %s event payload: %w", eventName, err)
+	}
+
+	r, ok := registry[eventName]
+	if !ok {
+		r = genericRenderer{eventName: eventName}
+	}
+
+	return r.Render(payload)
+}
+
+// ExtractStatus derives a success/failure/cancelled-style conclusion from
+// raw for event types that carry one (workflow_run, check_run), for
+// SLACK_NOTIFY_ON. Returns "" for event types with no such concept (e.g.
+// push, issues), meaning status-based notify rules don't apply.
+func ExtractStatus(eventName string, raw []byte) (string, error) {
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return "", fmt.Errorf("decoding %s event payload: %w", eventName, err)
+	}
+
+	switch eventName {
+	case "workflow_run":
+		return stringField(mapField(payload, "workflow_run"), "conclusion"), nil
+	case "check_run":
+		return stringField(mapField(payload, "check_run"), "conclusion"), nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+func TestRenderEventPush(t *testing.T) {
+	raw := []byte(`{
+		"ref": "refs/heads/main",
+		"repository": {"full_name": "acme/widgets"},
+		"pusher": {"name": "octocat"},
+		"commits": [
+			{"id": "aaaaaaaaaaaa", "message": "one\nmore detail", "author": {"name": "a"}},
+			{"id": "bbbbbbbbbbbb", "message": "two", "author": {"name": "b"}},
+			{"id": "cccccccccccc", "message": "three", "author": {"name": "c"}},
+			{"id": "dddddddddddd", "message": "four", "author": {"name": "d"}}
+		]
+	}`)
+
+	msg, err := RenderEvent("push", raw)
+	if err != nil {
+		t.Fatalf("RenderEvent(push) = %v", err)
+	}
+
+	if !strings.Contains(msg.Text, "acme/widgets") || !strings.Contains(msg.Text, "4 new commit(s)") {
+		t.Fatalf("Text = %q, want repo and commit count", msg.Text)
+	}
+	if len(msg.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2 (summary + commit list)", len(msg.Blocks))
+	}
+
+	list, ok := msg.Blocks[1].(*slack.Section)
+	if !ok || list.Text == nil {
+		t.Fatalf("second block isn't a section with text: %#v", msg.Blocks[1])
+	}
+	if strings.Count(list.Text.Text, "\n") != maxPushCommits {
+		t.Fatalf("commit list has %d lines, want %d (capped) + 1 overflow line", strings.Count(list.Text.Text, "\n")+1, maxPushCommits+1)
+	}
+	if !strings.Contains(list.Text.Text, "...and 1 more") {
+		t.Fatalf("commit list = %q, want an overflow line for the 4th commit", list.Text.Text)
+	}
+	if !strings.Contains(list.Text.Text, "one") || strings.Contains(list.Text.Text, "more detail") {
+		t.Fatalf("commit list = %q, want only the first line of a multi-line message", list.Text.Text)
+	}
+}
+
+func TestRenderEventPullRequestWithReviewers(t *testing.T) {
+	raw := []byte(`{
+		"action": "opened",
+		"pull_request": {
+			"number": 42,
+			"title": "Fix the thing",
+			"html_url": "https://github.com/acme/widgets/pull/42",
+			"base": {"ref": "main"},
+			"head": {"ref": "fix-branch"},
+			"requested_reviewers": [{"login": "alice"}, {"login": "bob"}]
+		}
+	}`)
+
+	msg, err := RenderEvent("pull_request", raw)
+	if err != nil {
+		t.Fatalf("RenderEvent(pull_request) = %v", err)
+	}
+
+	if !strings.Contains(msg.Text, "#42") || !strings.Contains(msg.Text, "opened") {
+		t.Fatalf("Text = %q, want PR number and action", msg.Text)
+	}
+
+	fields, ok := msg.Blocks[1].(*slack.Section)
+	if !ok {
+		t.Fatalf("second block isn't a section: %#v", msg.Blocks[1])
+	}
+	if len(fields.Fields) != 3 {
+		t.Fatalf("got %d fields, want 3 (branch, action, reviewers)", len(fields.Fields))
+	}
+	if !strings.Contains(fields.Fields[2].Text, "alice, bob") {
+		t.Fatalf("reviewers field = %q, want alice, bob", fields.Fields[2].Text)
+	}
+}
+
+func TestRenderEventPullRequestWithoutReviewers(t *testing.T) {
+	raw := []byte(`{
+		"action": "closed",
+		"pull_request": {
+			"number": 7,
+			"title": "Cleanup",
+			"html_url": "https://github.com/acme/widgets/pull/7",
+			"base": {"ref": "main"},
+			"head": {"ref": "cleanup"}
+		}
+	}`)
+
+	msg, err := RenderEvent("pull_request", raw)
+	if err != nil {
+		t.Fatalf("RenderEvent(pull_request) = %v", err)
+	}
+
+	fields, ok := msg.Blocks[1].(*slack.Section)
+	if !ok {
+		t.Fatalf("second block isn't a section: %#v", msg.Blocks[1])
+	}
+	if len(fields.Fields) != 2 {
+		t.Fatalf("got %d fields, want 2 (branch, action) when there are no reviewers", len(fields.Fields))
+	}
+}
+
+func TestRenderEventUnknownFallsBackToGeneric(t *testing.T) {
+	raw := []byte(`{
+		"action": "created",
+		"repository": {"full_name": "acme/widgets"},
+		"sender": {"login": "octocat"}
+	}`)
+
+	msg, err := RenderEvent("discussion", raw)
+	if err != nil {
+		t.Fatalf("RenderEvent(discussion) = %v", err)
+	}
+
+	if !strings.Contains(msg.Text, "discussion") || !strings.Contains(msg.Text, "acme/widgets") {
+		t.Fatalf("Text = %q, want event name and repo from the generic fallback", msg.Text)
+	}
+
+	fields, ok := msg.Blocks[1].(*slack.Section)
+	if !ok || len(fields.Fields) < 2 || !strings.Contains(fields.Fields[1].Text, "octocat") {
+		t.Fatalf("expected the sender field to mention octocat, got %#v", msg.Blocks)
+	}
+}
+
+func TestRenderEventInvalidJSON(t *testing.T) {
+	if _, err := RenderEvent("push", []byte("not json")); err == nil {
+		t.Fatal("RenderEvent with invalid JSON = nil error, want one")
+	}
+}
+
+func TestExtractStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		eventName string
+		raw       string
+		want      string
+	}{
+		{"workflow_run conclusion", "workflow_run", `{"workflow_run":{"conclusion":"success"}}`, "success"},
+		{"check_run conclusion", "check_run", `{"check_run":{"conclusion":"failure"}}`, "failure"},
+		{"event without a conclusion concept", "push", `{"ref":"refs/heads/main"}`, ""},
+		{"workflow_run still in progress", "workflow_run", `{"workflow_run":{"status":"in_progress"}}`, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ExtractStatus(c.eventName, []byte(c.raw))
+			if err != nil {
+				t.Fatalf("ExtractStatus(%q) = %v", c.eventName, err)
+			}
+			if got != c.want {
+				t.Fatalf("ExtractStatus(%q) = %q, want %q", c.eventName, got, c.want)
+			}
+		})
+	}
+}
+
+func TestExtractStatusInvalidJSON(t *testing.T) {
+	if _, err := ExtractStatus("workflow_run", []byte("not json")); err == nil {
+		t.Fatal("ExtractStatus with invalid JSON = nil error, want one")
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+// genericRenderer handles any event without a dedicated Renderer. It falls
+// back to whatever top-level fields GitHub webhook payloads commonly share.
+type genericRenderer struct {
+	eventName string
+}
+
+func (g genericRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	repo := mapField(payload, "repository")
+	sender := mapField(payload, "sender")
+	action := stringField(payload, "action")
+
+	text := fmt.Sprintf("*%s*: `%s` event", stringField(repo, "full_name"), g.eventName)
+	if action != "" {
+		text = fmt.Sprintf("%s (%s)", text, action)
+	}
+
+	fields := []*slack.Text{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Event:*\n%s", g.eventName)},
+	}
+	if login := stringField(sender, "login"); login != "" {
+		fields = append(fields, &slack.Text{Type: "mrkdwn", Text: fmt.Sprintf("*Sender:*\n%s", login)})
+	}
+
+	return &slack.Message{
+		Text: text,
+		Blocks: []any{
+			&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}},
+			&slack.Section{Type: "section", Fields: fields},
+		},
+	}, nil
+}
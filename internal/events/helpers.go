@@ -0,0 +1,53 @@
+package events
+
+import "strings"
+
+func stringField(m map[string]any, key string) string {
+	if v, ok := m[key]; ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func mapField(m map[string]any, key string) map[string]any {
+	if v, ok := m[key]; ok {
+		if mm, ok := v.(map[string]any); ok {
+			return mm
+		}
+	}
+	return nil
+}
+
+func sliceField(m map[string]any, key string) []any {
+	if v, ok := m[key]; ok {
+		if s, ok := v.([]any); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+func floatField(m map[string]any, key string) float64 {
+	if v, ok := m[key]; ok {
+		if f, ok := v.(float64); ok {
+			return f
+		}
+	}
+	return 0
+}
+
+// firstLine returns the text up to the first newline.
+func firstLine(s string) string {
+	return strings.SplitN(s, "\n", 2)[0]
+}
+
+// truncate shortens s to n runes, appending an ellipsis if anything was cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
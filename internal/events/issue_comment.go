@@ -0,0 +1,29 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type issueCommentRenderer struct{}
+
+func init() { register("issue_comment", issueCommentRenderer{}) }
+
+func (issueCommentRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	issue := mapField(payload, "issue")
+	comment := mapField(payload, "comment")
+	action := stringField(payload, "action")
+
+	text := fmt.Sprintf("*<%s|#%d %s>* comment %s by %s",
+		stringField(issue, "html_url"), int(floatField(issue, "number")), stringField(issue, "title"),
+		action, stringField(mapField(comment, "user"), "login"))
+
+	return &slack.Message{
+		Text: text,
+		Blocks: []any{
+			&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}},
+			&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: truncate(stringField(comment, "body"), 500)}},
+		},
+	}, nil
+}
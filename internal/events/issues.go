@@ -0,0 +1,24 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type issuesRenderer struct{}
+
+func init() { register("issues", issuesRenderer{}) }
+
+func (issuesRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	issue := mapField(payload, "issue")
+	action := stringField(payload, "action")
+
+	text := fmt.Sprintf("*<%s|#%d %s>* issue %s",
+		stringField(issue, "html_url"), int(floatField(issue, "number")), stringField(issue, "title"), action)
+
+	return &slack.Message{
+		Text:   text,
+		Blocks: []any{&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}}},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type pullRequestRenderer struct{}
+
+func init() { register("pull_request", pullRequestRenderer{}) }
+
+func (pullRequestRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	pr := mapField(payload, "pull_request")
+	action := stringField(payload, "action")
+	base := mapField(pr, "base")
+	head := mapField(pr, "head")
+
+	text := fmt.Sprintf("*<%s|#%d %s>* pull request %s",
+		stringField(pr, "html_url"), int(floatField(pr, "number")), stringField(pr, "title"), action)
+
+	fields := []*slack.Text{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Branch:*\n%s -> %s", stringField(head, "ref"), stringField(base, "ref"))},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Action:*\n%s", action)},
+	}
+
+	reviewers := sliceField(pr, "requested_reviewers")
+	if len(reviewers) > 0 {
+		names := make([]string, 0, len(reviewers))
+		for _, r := range reviewers {
+			if rm, ok := r.(map[string]any); ok {
+				names = append(names, stringField(rm, "login"))
+			}
+		}
+		fields = append(fields, &slack.Text{Type: "mrkdwn", Text: fmt.Sprintf("*Reviewers:*\n%s", strings.Join(names, ", "))})
+	}
+
+	return &slack.Message{
+		Text: text,
+		Blocks: []any{
+			&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}},
+			&slack.Section{Type: "section", Fields: fields},
+		},
+	}, nil
+}
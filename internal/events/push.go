@@ -0,0 +1,59 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+// maxPushCommits caps how many individual commits are listed in a push
+// notification, mirroring Gitea/Gogs' SlackPayloadPush.
+const maxPushCommits = 3
+
+type pushRenderer struct{}
+
+func init() { register("push", pushRenderer{}) }
+
+func (pushRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	repo := mapField(payload, "repository")
+	pusher := mapField(payload, "pusher")
+	commits := sliceField(payload, "commits")
+
+	text := fmt.Sprintf("*%s*: %d new commit(s) pushed to `%s` by %s",
+		stringField(repo, "full_name"), len(commits), refName(stringField(payload, "ref")), stringField(pusher, "name"))
+
+	lines := make([]string, 0, maxPushCommits+1)
+	for i, c := range commits {
+		if i >= maxPushCommits {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(commits)-maxPushCommits))
+			break
+		}
+		commit, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		author := mapField(commit, "author")
+		lines = append(lines, fmt.Sprintf("`%s` %s - %s",
+			truncate(stringField(commit, "id"), 7), truncate(firstLine(stringField(commit, "message")), 72), stringField(author, "name")))
+	}
+
+	blocks := []any{
+		&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}},
+	}
+	if len(lines) > 0 {
+		blocks = append(blocks, &slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: strings.Join(lines, "\n")}})
+	}
+
+	return &slack.Message{Text: text, Blocks: blocks}, nil
+}
+
+// refName trims the refs/heads/ or refs/tags/ prefix GitHub sends.
+func refName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ref
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type releaseRenderer struct{}
+
+func init() { register("release", releaseRenderer{}) }
+
+func (releaseRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	release := mapField(payload, "release")
+	action := stringField(payload, "action")
+
+	name := stringField(release, "name")
+	if name == "" {
+		name = stringField(release, "tag_name")
+	}
+
+	text := fmt.Sprintf("*<%s|%s>* release %s", stringField(release, "html_url"), name, action)
+
+	return &slack.Message{
+		Text:   text,
+		Blocks: []any{&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}}},
+	}, nil
+}
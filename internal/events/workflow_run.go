@@ -0,0 +1,28 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+type workflowRunRenderer struct{}
+
+func init() { register("workflow_run", workflowRunRenderer{}) }
+
+func (workflowRunRenderer) Render(payload map[string]any) (*slack.Message, error) {
+	run := mapField(payload, "workflow_run")
+
+	status := stringField(run, "conclusion")
+	if status == "" {
+		status = stringField(run, "status")
+	}
+
+	text := fmt.Sprintf("*<%s|%s #%d>* %s",
+		stringField(run, "html_url"), stringField(run, "name"), int(floatField(run, "run_number")), status)
+
+	return &slack.Message{
+		Text:   text,
+		Blocks: []any{&slack.Section{Type: "section", Text: &slack.Text{Type: "mrkdwn", Text: text}}},
+	}, nil
+}
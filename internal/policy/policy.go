@@ -0,0 +1,74 @@
+// Package policy decides whether a run's status is worth notifying about,
+// based on a SLACK_NOTIFY_ON rule list.
+package policy
+
+import "strings"
+
+// Rule is one predicate from SLACK_NOTIFY_ON.
+type Rule string
+
+const (
+	RuleSuccess             Rule = "success"
+	RuleFailure             Rule = "failure"
+	RuleCancelled           Rule = "cancelled"
+	RuleSuccessAfterFailure Rule = "success-after-failure"
+	RuleChanged             Rule = "changed"
+)
+
+// ParseRules splits a comma-separated SLACK_NOTIFY_ON value into Rules,
+// trimming whitespace and dropping empty entries.
+func ParseRules(raw string) []Rule {
+	if raw == "" {
+		return nil
+	}
+
+	fields := strings.Split(raw, ",")
+	rules := make([]Rule, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		rules = append(rules, Rule(f))
+	}
+
+	return rules
+}
+
+// Evaluate reports whether status should trigger a notification given
+// rules. previous is the conclusion of the most recent prior run of the
+// same workflow on the same branch, or "" if it couldn't be determined.
+// An empty rules list always notifies, preserving the default "notify on
+// every run" behavior.
+func Evaluate(rules []Rule, status, previous string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	for _, r := range rules {
+		switch r {
+		case RuleSuccess:
+			if status == "success" {
+				return true
+			}
+		case RuleFailure:
+			if status == "failure" {
+				return true
+			}
+		case RuleCancelled:
+			if status == "cancelled" {
+				return true
+			}
+		case RuleSuccessAfterFailure:
+			if status == "success" && previous == "failure" {
+				return true
+			}
+		case RuleChanged:
+			if previous != "" && previous != status {
+				return true
+			}
+		}
+	}
+
+	return false
+}
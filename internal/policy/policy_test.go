@@ -0,0 +1,59 @@
+package policy
+
+import "testing"
+
+func TestParseRules(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want []Rule
+	}{
+		{"", nil},
+		{"failure", []Rule{RuleFailure}},
+		{"failure,cancelled", []Rule{RuleFailure, RuleCancelled}},
+		{" success , changed ,", []Rule{RuleSuccess, RuleChanged}},
+	}
+
+	for _, c := range cases {
+		got := ParseRules(c.raw)
+		if len(got) != len(c.want) {
+			t.Fatalf("ParseRules(%q) = %v, want %v", c.raw, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("ParseRules(%q) = %v, want %v", c.raw, got, c.want)
+			}
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		name     string
+		rules    []Rule
+		status   string
+		previous string
+		want     bool
+	}{
+		{"no rules always notifies", nil, "success", "", true},
+		{"failure rule matches failure", []Rule{RuleFailure}, "failure", "", true},
+		{"failure rule skips success", []Rule{RuleFailure}, "success", "", false},
+		{"cancelled rule matches cancelled", []Rule{RuleCancelled}, "cancelled", "", true},
+		{"success rule matches success", []Rule{RuleSuccess}, "success", "", true},
+		{"success-after-failure matches", []Rule{RuleSuccessAfterFailure}, "success", "failure", true},
+		{"success-after-failure skips repeat success", []Rule{RuleSuccessAfterFailure}, "success", "success", false},
+		{"success-after-failure skips failure", []Rule{RuleSuccessAfterFailure}, "failure", "failure", false},
+		{"changed matches differing conclusion", []Rule{RuleChanged}, "failure", "success", true},
+		{"changed skips unchanged conclusion", []Rule{RuleChanged}, "success", "success", false},
+		{"changed skips unknown previous", []Rule{RuleChanged}, "success", "", false},
+		{"any rule in the list matching is enough", []Rule{RuleFailure, RuleCancelled}, "cancelled", "", true},
+		{"none matching skips", []Rule{RuleFailure, RuleCancelled}, "success", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Evaluate(c.rules, c.status, c.previous); got != c.want {
+				t.Errorf("Evaluate(%v, %q, %q) = %v, want %v", c.rules, c.status, c.previous, got, c.want)
+			}
+		})
+	}
+}
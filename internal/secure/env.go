@@ -0,0 +1,28 @@
+package secure
+
+import (
+	"regexp"
+	"strings"
+)
+
+var secretEnvNamePattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|key|credential)`)
+
+// SecretEnvValues returns the values of environment entries (in the
+// "NAME=value" form os.Environ() uses) whose name looks like it holds a
+// secret, e.g. *_TOKEN, *_SECRET, *_PASSWORD. Values shorter than 6 bytes
+// are skipped, since masking them risks mangling unrelated payload text.
+func SecretEnvValues(environ []string) []string {
+	var values []string
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || len(value) < 6 {
+			continue
+		}
+		if secretEnvNamePattern.MatchString(name) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,57 @@
+// Package secure applies cross-cutting safety to an outgoing Slack
+// payload: redacting secret values before they leave the runner, and
+// signing the body so a receiving proxy can verify it actually came from
+// this action.
+package secure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const mask = "***"
+
+// Redactor scans a payload for configured secret values and regex
+// patterns, replacing matches with "***" before it's sent anywhere.
+type Redactor struct {
+	literals []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor builds a Redactor masking each of literals (exact-value
+// matches, e.g. values of env vars that look like secrets) and anything
+// matching patterns (regexes, e.g. from SLACK_REDACT_PATTERNS).
+func NewRedactor(literals, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, l := range literals {
+		if l != "" {
+			r.literals = append(r.literals, l)
+		}
+	}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("compiling redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Redact returns body with every literal and pattern match replaced by ***.
+func (r *Redactor) Redact(body []byte) []byte {
+	s := string(body)
+
+	for _, l := range r.literals {
+		s = strings.ReplaceAll(s, l, mask)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, mask)
+	}
+
+	return []byte(s)
+}
@@ -0,0 +1,17 @@
+package secure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sign computes an HMAC-SHA256 digest of body keyed by secret, hex-encoded,
+// for callers to attach as a webhook signature header (e.g.
+// X-Hub-Signature-256 or X-Slack-Signature) so a reverse proxy can verify
+// the request came from this action before forwarding it to Slack.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+// Package slack contains the Slack incoming-webhook message types shared by
+// the status-ping builder in main and the per-event renderers in
+// internal/events.
+package slack
+
+// Attachment color
+const (
+	ColorSuccess   = "#2eb886"
+	ColorFailure   = "#951e13"
+	ColorCancelled = "#dddddd"
+)
+
+// Message Slack incoming webhook message
+type Message struct {
+	Text        string       `json:"text,omitempty"` // fallback string
+	Blocks      []any        `json:"blocks,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	ThreadTS    string       `json:"thread_ts,omitempty"`
+	Markdown    bool         `json:"mrkdwn,omitempty"` // default: true
+	Username    string       `json:"username,omitempty"`
+	IconURL     string       `json:"icon_url,omitempty"`
+}
+
+// Attachment Slack incoming webhook attachment
+type Attachment struct {
+	Blocks []any  `json:"blocks,omitempty"`
+	Color  string `json:"color,omitempty"`
+}
+
+// Context Slack incoming webhook context block
+type Context struct {
+	Type     string `json:"type"`               // always `context`
+	Elements []any  `json:"elements"`           // one of Image and Text, maximum size: 10
+	BlockID  string `json:"block_id,omitempty"` // maximum length: 255
+}
+
+// Section Slack incoming webhook section block
+type Section struct {
+	Type      string  `json:"type"`                // always `section`
+	Text      *Text   `json:"text,omitempty"`      // maximum length: 3000
+	BlockID   string  `json:"block_id,omitempty"`  // maximum length: 255
+	Fields    []*Text `json:"fields,omitempty"`    // maximum size: 10
+	Accessory any     `json:"accessory,omitempty"` // one of block elements
+}
+
+// Image Slack incoming webhook image block element
+type Image struct {
+	Type     string `json:"type"` // always `image`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"` // plain text
+}
+
+// Text Slack incoming webhook text composition object
+type Text struct {
+	Type     string `json:"type"` // `plain_text` or `mrkdwn`
+	Text     string `json:"text"`
+	Emoji    bool   `json:"emoji,omitempty"`    // only usable for `plain_text`
+	Verbatim bool   `json:"verbatim,omitempty"` // only usable for `mrkdwn`
+}
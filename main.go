@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
+	"text/template"
+
+	"github.com/onionyst/action-slack-notify/internal/events"
+	"github.com/onionyst/action-slack-notify/internal/policy"
+	"github.com/onionyst/action-slack-notify/internal/slack"
 )
 
 // GitHub Actions environment variables
@@ -19,6 +23,7 @@ const (
 	EnvGitHubRunID     = "GITHUB_RUN_ID"
 	EnvGitHubRunNumber = "GITHUB_RUN_NUMBER"
 	EnvGitHubWorkflow  = "GITHUB_WORKFLOW"
+	EnvGitHubEventPath = "GITHUB_EVENT_PATH"
 )
 
 // Slack environment variables
@@ -32,63 +37,253 @@ const (
 	EnvSlackEmail      = "SLACK_EMAIL"
 	EnvSlackStatus     = "SLACK_STATUS"
 	EnvSlackWebhookURL = "SLACK_WEBHOOK_URL"
-)
 
-// Slack attachment color
-const (
-	ColorSuccess   = "#2eb886"
-	ColorFailure   = "#951e13"
-	ColorCancelled = "#dddddd"
+	// EnvSlackTemplate is a path to a text/template file, or the template
+	// source itself, that overrides the built-in block layout.
+	EnvSlackTemplate = "SLACK_TEMPLATE"
+	// EnvSlackTemplateVars is a JSON object exposed to the template as .Vars.
+	EnvSlackTemplateVars = "SLACK_TEMPLATE_VARS"
+
+	// EnvSlackUsername is the action-level default bot display name. A
+	// rendered SLACK_TEMPLATE's own "username" field still overrides it.
+	EnvSlackUsername = "SLACK_USERNAME"
+	// EnvSlackIconURL is the action-level default bot icon. A rendered
+	// SLACK_TEMPLATE's own "icon_url" field still overrides it.
+	EnvSlackIconURL = "SLACK_ICON_URL"
+
+	// EnvSlackEventMode switches main from the status-ping layout to the
+	// per-GitHub-event renderers in internal/events.
+	EnvSlackEventMode = "SLACK_EVENT_MODE"
+
+	// EnvSlackNotifyOn is a comma-separated list of policy.Rules; when set,
+	// a run is only posted if one of them matches. Unset notifies always.
+	EnvSlackNotifyOn = "SLACK_NOTIFY_ON"
 )
 
-// Message Slack incoming webhook message
-type Message struct {
-	Text        string       `json:"text,omitempty"` // fallback string
-	Blocks      []any        `json:"blocks,omitempty"`
-	Attachments []Attachment `json:"attachments,omitempty"`
-	ThreadTS    string       `json:"thread_ts,omitempty"`
-	Markdown    bool         `json:"mrkdwn,omitempty"` // default: true
+// GithubContext workflow run metadata exposed to templates as .Github
+type GithubContext struct {
+	EventName string
+	Ref       string
+	Repo      string
+	Owner     string
+	RunID     string
+	RunNumber string
+	Workflow  string
 }
 
-// Attachment Slack incoming webhook attachment
-type Attachment struct {
-	Blocks []any  `json:"blocks,omitempty"`
-	Color  string `json:"color,omitempty"`
+// CommitContext commit metadata exposed to templates as .Commit
+type CommitContext struct {
+	ID         string
+	Message    string
+	URL        string
+	Author     string
+	Email      string
+	CompareURL string
+	AvatarURL  string
 }
 
-// Context Slack incoming webhook context block
-type Context struct {
-	Type     string `json:"type"`               // always `context`
-	Elements []any  `json:"elements"`           // one of Image and Text, maximum size: 10
-	BlockID  string `json:"block_id,omitempty"` // maximum length: 255
+// TemplateData root object passed to a user-supplied SLACK_TEMPLATE
+type TemplateData struct {
+	Github GithubContext
+	Commit CommitContext
+	Status string
+	Vars   map[string]any
 }
 
-// Section Slack incoming webhook section block
-type Section struct {
-	Type      string  `json:"type"`                // always `section`
-	Text      *Text   `json:"text,omitempty"`      // maximum length: 3000
-	BlockID   string  `json:"block_id,omitempty"`  // maximum length: 255
-	Fields    []*Text `json:"fields,omitempty"`    // maximum size: 10
-	Accessory any     `json:"accessory,omitempty"` // one of block elements
+// TemplatePayload is the shape a rendered template's JSON output is parsed
+// into. It mirrors slack.Message so a template can either emit a
+// fully-formed payload or just the fields it wants to override; zero-value
+// fields are left untouched and the built-in layout's values are kept.
+type TemplatePayload struct {
+	Text        string             `json:"text,omitempty"`
+	Blocks      []any              `json:"blocks,omitempty"`
+	Attachments []slack.Attachment `json:"attachments,omitempty"`
+	ThreadTS    string             `json:"thread_ts,omitempty"`
+	Username    string             `json:"username,omitempty"`
+	IconURL     string             `json:"icon_url,omitempty"`
 }
 
-// Image Slack incoming webhook image block element
-type Image struct {
-	Type     string `json:"type"` // always `image`
-	ImageURL string `json:"image_url"`
-	AltText  string `json:"alt_text"` // plain text
+// renderTemplate parses and executes the template named by SLACK_TEMPLATE,
+// applying it on top of the default payload. src is either a path to a
+// template file or the template source itself. Parse errors are returned
+// as-is: text/template already reports them with a "name:line:col" prefix.
+func renderTemplate(src string, data TemplateData, payload *slack.Message) error {
+	body := src
+	if content, err := os.ReadFile(src); err == nil {
+		body = string(content)
+	}
+
+	tmpl, err := template.New("slack_template").Parse(body)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", EnvSlackTemplate, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("executing %s: %w", EnvSlackTemplate, err)
+	}
+
+	var out TemplatePayload
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		return fmt.Errorf("%s did not render valid JSON: %w", EnvSlackTemplate, err)
+	}
+
+	if out.Text != "" {
+		payload.Text = out.Text
+	}
+	if out.Blocks != nil {
+		payload.Blocks = out.Blocks
+	}
+	if out.Attachments != nil {
+		payload.Attachments = out.Attachments
+	}
+	if out.ThreadTS != "" {
+		payload.ThreadTS = out.ThreadTS
+	}
+	if out.Username != "" {
+		payload.Username = out.Username
+	}
+	if out.IconURL != "" {
+		payload.IconURL = out.IconURL
+	}
+
+	return nil
 }
 
-// Text Slack incoming webhook text composition object
-type Text struct {
-	Type     string `json:"type"` // `plain_text` or `mrkdwn`
-	Text     string `json:"text"`
-	Emoji    bool   `json:"emoji,omitempty"`    // only usable for `plain_text`
-	Verbatim bool   `json:"verbatim,omitempty"` // only usable for `mrkdwn`
+// applyActionDefaults sets payload's username/icon_url from the
+// action-level SLACK_USERNAME/SLACK_ICON_URL inputs. Applied before
+// SLACK_TEMPLATE runs, so a template's own username/icon_url still wins.
+func applyActionDefaults(payload *slack.Message) {
+	if username := os.Getenv(EnvSlackUsername); username != "" {
+		payload.Username = username
+	}
+	if iconURL := os.Getenv(EnvSlackIconURL); iconURL != "" {
+		payload.IconURL = iconURL
+	}
+}
+
+// githubContextFromEnv builds the .Github value exposed to SLACK_TEMPLATE,
+// shared by statusPayload and renderEventPayload. eventName is passed in
+// separately since GITHUB_EVENT_NAME is read once by the caller either way.
+func githubContextFromEnv(eventName string) GithubContext {
+	return GithubContext{
+		EventName: eventName,
+		Ref:       os.Getenv(EnvGitHubRef),
+		Repo:      os.Getenv(EnvGitHubRepo),
+		Owner:     os.Getenv(EnvGitHubRepoOwner),
+		RunID:     os.Getenv(EnvGitHubRunID),
+		RunNumber: os.Getenv(EnvGitHubRunNumber),
+		Workflow:  os.Getenv(EnvGitHubWorkflow),
+	}
+}
+
+// parseTemplateVars parses SLACK_TEMPLATE_VARS, if set, into the map
+// exposed to templates as .Vars.
+func parseTemplateVars(raw string) (map[string]any, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var vars map[string]any
+	if err := json.Unmarshal([]byte(raw), &vars); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", EnvSlackTemplateVars, err)
+	}
+
+	return vars, nil
 }
 
 func main() {
+	eventMode := isTruthy(os.Getenv(EnvSlackEventMode))
+
+	var payload slack.Message
+	if eventMode {
+		eventName, raw := readEventPayload()
+		if !shouldNotifyEvent(eventName, raw) {
+			return
+		}
+		payload = renderEventPayload(eventName, raw)
+	} else {
+		if !shouldNotify(getEnv(EnvSlackStatus)) {
+			return
+		}
+		payload = statusPayload()
+	}
+
+	if token := os.Getenv(EnvSlackBotToken); token != "" {
+		channel := getEnv(EnvSlackChannel)
+
+		// sendWebAPI can fail (post/update error, no ts) or partially
+		// succeed (post/update ok, a later files.upload error, ts set) -
+		// write whatever ts/channelID it did get before acting on err, so
+		// a successfully-posted message isn't orphaned for chat.update.
+		ts, channelID, err := sendWebAPI(token, channel, payload)
+		if ts != "" {
+			if outErr := writeGithubOutput("message_ts", ts); outErr != nil {
+				fmt.Fprintf(os.Stderr, "Writing %s: %s\n", EnvGitHubOutput, outErr)
+				os.Exit(1)
+			}
+			if outErr := writeGithubOutput("channel_id", channelID); outErr != nil {
+				fmt.Fprintf(os.Stderr, "Writing %s: %s\n", EnvGitHubOutput, outErr)
+				os.Exit(1)
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Payload send failed: %s\n", err)
+			os.Exit(2)
+		}
+
+		return
+	}
+
 	webhookURL := getEnv(EnvSlackWebhookURL)
+	if err := send(webhookURL, payload); err != nil {
+		fmt.Fprintf(os.Stderr, "Payload send failed: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// shouldNotify evaluates SLACK_NOTIFY_ON against status and, for rules
+// that need it, the previous run's conclusion. An empty status (event
+// types with no pass/fail concept, e.g. push) always notifies, since
+// status-based rules can't be evaluated against it.
+func shouldNotify(status string) bool {
+	rules := policy.ParseRules(os.Getenv(EnvSlackNotifyOn))
+	if len(rules) == 0 || status == "" {
+		return true
+	}
+
+	previous, err := previousRunConclusion(
+		os.Getenv(EnvGitHubToken),
+		os.Getenv(EnvGitHubAPIURL),
+		os.Getenv(EnvGitHubRepo),
+		os.Getenv(EnvGitHubWorkflowRef),
+		os.Getenv(EnvGitHubRef),
+		os.Getenv(EnvGitHubRunID),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Checking previous run: %s\n", err)
+	}
+
+	return policy.Evaluate(rules, status, previous)
+}
+
+// shouldNotifyEvent is shouldNotify for SLACK_EVENT_MODE, which has no
+// SLACK_STATUS input: the status instead comes from whatever conclusion
+// the event payload itself carries, if any.
+func shouldNotifyEvent(eventName string, raw []byte) bool {
+	status, err := events.ExtractStatus(eventName, raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Extracting status: %s\n", err)
+		return true
+	}
+
+	return shouldNotify(status)
+}
+
+// statusPayload builds the built-in "job status ping" layout, optionally
+// overridden by SLACK_TEMPLATE.
+func statusPayload() slack.Message {
 	status := getEnv(EnvSlackStatus)
 	author := getEnv(EnvSlackAuthor)
 	email := getEnv(EnvSlackEmail)
@@ -101,9 +296,9 @@ func main() {
 	commitMsg = strings.Split(commitMsg, "\n")[0]
 
 	statusColors := map[string]string{
-		"success":   ColorSuccess,
-		"failure":   ColorFailure,
-		"cancelled": ColorCancelled,
+		"success":   slack.ColorSuccess,
+		"failure":   slack.ColorFailure,
+		"cancelled": slack.ColorCancelled,
 	}
 	color, ok := statusColors[status]
 	if !ok {
@@ -119,37 +314,37 @@ func main() {
 	runNumber := os.Getenv(EnvGitHubRunNumber)
 	workflow := os.Getenv(EnvGitHubWorkflow)
 
-	payload := Message{
+	payload := slack.Message{
 		Text: fmt.Sprintf("GitHub Actions (%s): %s %s", repo, workflow, status),
 		Blocks: []any{
-			&Context{
+			&slack.Context{
 				Type: "context",
 				Elements: []any{
-					&Image{
+					&slack.Image{
 						Type:     "image",
 						ImageURL: avatarURL,
 						AltText:  owner,
 					},
-					&Text{
+					&slack.Text{
 						Type: "mrkdwn",
 						Text: fmt.Sprintf("*%s*", repo),
 					},
 				},
 			},
 		},
-		Attachments: []Attachment{
+		Attachments: []slack.Attachment{
 			{
 				Blocks: []any{
-					&Section{
+					&slack.Section{
 						Type: "section",
-						Text: &Text{
+						Text: &slack.Text{
 							Type: "mrkdwn",
 							Text: fmt.Sprintf("*<https://github.com/%s/actions/runs/%s|%s #%s>*", repo, runID, workflow, runNumber),
 						},
 					},
-					&Section{
+					&slack.Section{
 						Type: "section",
-						Fields: []*Text{
+						Fields: []*slack.Text{
 							{
 								Type: "mrkdwn",
 								Text: fmt.Sprintf("*Ref:*\n<%s|%s>", compareURL, ref),
@@ -168,9 +363,9 @@ func main() {
 							},
 						},
 					},
-					&Section{
+					&slack.Section{
 						Type: "section",
-						Text: &Text{
+						Text: &slack.Text{
 							Type: "mrkdwn",
 							Text: fmt.Sprintf("*Message:*\n<%s|%s (%s)>", commitURL, commitMsg, commitID[:8]),
 						},
@@ -181,10 +376,93 @@ func main() {
 		},
 	}
 
-	if err := send(webhookURL, payload); err != nil {
-		fmt.Fprintf(os.Stderr, "Payload send failed: %s\n", err)
-		os.Exit(2)
+	applyActionDefaults(&payload)
+
+	if tplSrc := os.Getenv(EnvSlackTemplate); tplSrc != "" {
+		vars, err := parseTemplateVars(os.Getenv(EnvSlackTemplateVars))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		data := TemplateData{
+			Github: githubContextFromEnv(event),
+			Commit: CommitContext{
+				ID:         commitID,
+				Message:    commitMsg,
+				URL:        commitURL,
+				Author:     author,
+				Email:      email,
+				CompareURL: compareURL,
+				AvatarURL:  avatarURL,
+			},
+			Status: status,
+			Vars:   vars,
+		}
+
+		if err := renderTemplate(tplSrc, data, &payload); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	return payload
+}
+
+// readEventPayload reads the GitHub webhook event from GITHUB_EVENT_PATH.
+func readEventPayload() (eventName string, raw []byte) {
+	eventName = getEnv(EnvGitHubEventName)
+	eventPath := getEnv(EnvGitHubEventPath)
+
+	raw, err := os.ReadFile(eventPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading %s: %s\n", EnvGitHubEventPath, err)
+		os.Exit(1)
+	}
+
+	return eventName, raw
+}
+
+// renderEventPayload renders raw with internal/events, tailored to
+// eventName, then applies the same action-level defaults and SLACK_TEMPLATE
+// override statusPayload does, so SLACK_TEMPLATE isn't silently ignored
+// under SLACK_EVENT_MODE.
+func renderEventPayload(eventName string, raw []byte) slack.Message {
+	rendered, err := events.RenderEvent(eventName, raw)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+
+	payload := *rendered
+	applyActionDefaults(&payload)
+
+	if tplSrc := os.Getenv(EnvSlackTemplate); tplSrc != "" {
+		status, err := events.ExtractStatus(eventName, raw)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		vars, err := parseTemplateVars(os.Getenv(EnvSlackTemplateVars))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		data := TemplateData{
+			Github: githubContextFromEnv(eventName),
+			Status: status,
+			Vars:   vars,
+		}
+
+		if err := renderTemplate(tplSrc, data, &payload); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	return payload
 }
 
 func getEnv(key string) string {
@@ -197,6 +475,15 @@ func getEnv(key string) string {
 	return value
 }
 
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
 func jsonMarshal(t any) ([]byte, error) {
 	buffer := &bytes.Buffer{}
 	encoder := json.NewEncoder(buffer)
@@ -204,23 +491,3 @@ func jsonMarshal(t any) ([]byte, error) {
 	err := encoder.Encode(t)
 	return buffer.Bytes(), err
 }
-
-func send(webhookURL string, payload Message) error {
-	enc, err := jsonMarshal(payload)
-	if err != nil {
-		return err
-	}
-
-	b := bytes.NewBuffer(enc)
-
-	res, err := http.Post(webhookURL, "application/json", b)
-	if err != nil {
-		return err
-	}
-	if res.StatusCode != 200 {
-		return fmt.Errorf("Error on message: %s", res.Status)
-	}
-
-	fmt.Println(res.Status)
-	return nil
-}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onionyst/action-slack-notify/internal/secure"
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+// Send-related environment variables
+const (
+	// EnvSlackMaxRetries caps retries for 429/5xx responses. Default 5.
+	EnvSlackMaxRetries = "SLACK_MAX_RETRIES"
+	// EnvSlackTimeout bounds each individual attempt, as a time.Duration
+	// string (e.g. "10s"). Default 10s.
+	EnvSlackTimeout = "SLACK_TIMEOUT"
+
+	// EnvSlackRedactPatterns is a newline-separated list of regexes; any
+	// match in the final payload is replaced with *** before it's sent.
+	EnvSlackRedactPatterns = "SLACK_REDACT_PATTERNS"
+	// EnvSlackSigningSecret, when set, signs the payload body and attaches
+	// the digest as an X-Hub-Signature-256/X-Slack-Signature header.
+	EnvSlackSigningSecret = "SLACK_SIGNING_SECRET"
+)
+
+// httpClient is reused across attempts/sends so TCP and TLS connections to
+// Slack are kept alive. Transport is left nil (http.DefaultTransport) but
+// can be swapped out, e.g. in tests that inject canned responses.
+var httpClient = &http.Client{}
+
+// SlackError is returned by send when Slack responds with a non-2xx
+// status, so callers can tell permanent failures (bad webhook, bad
+// payload) apart from transient ones worth retrying.
+type SlackError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *SlackError) Error() string {
+	return fmt.Sprintf("slack webhook returned %s: %s", http.StatusText(e.StatusCode), e.Body)
+}
+
+// Temporary reports whether this error is worth retrying. Mirrors the
+// net.Error convention.
+func (e *SlackError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+		return false
+	default:
+		return true
+	}
+}
+
+func send(webhookURL string, payload slack.Message) error {
+	enc, err := jsonMarshal(payload)
+	if err != nil {
+		return err
+	}
+
+	enc, signature, err := secureBody(enc)
+	if err != nil {
+		return err
+	}
+
+	timeout := envDuration(EnvSlackTimeout, 10*time.Second)
+
+	_, err = withRetry(func() (struct{}, error) {
+		return struct{}{}, attemptSend(webhookURL, enc, signature, timeout)
+	})
+	return err
+}
+
+// withRetry runs attempt until it succeeds, returns a permanent SlackError,
+// or SLACK_MAX_RETRIES is exhausted, backing off between tries. Shared by
+// the incoming-webhook path (send) and the Slack Web API path (webapi.go)
+// so both get the same 429/5xx retry handling.
+func withRetry[T any](attempt func() (T, error)) (T, error) {
+	maxRetries := envInt(EnvSlackMaxRetries, 5)
+
+	var lastErr error
+	for n := 0; ; n++ {
+		result, err := attempt()
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		var serr *SlackError
+		if !errors.As(err, &serr) || !serr.Temporary() {
+			var zero T
+			return zero, lastErr
+		}
+		if n >= maxRetries {
+			var zero T
+			return zero, lastErr
+		}
+
+		wait := backoff(n)
+		if errors.As(err, &serr) && serr.RetryAfter > 0 {
+			wait = serr.RetryAfter
+		}
+		time.Sleep(wait)
+	}
+}
+
+// secureBody redacts body per SLACK_REDACT_PATTERNS/secret-looking env
+// values and, if SLACK_SIGNING_SECRET is set, signs the redacted body.
+// Shared by the incoming-webhook path (send) and the Slack Web API JSON
+// calls (webapi.go) so both apply the same cross-cutting redaction/signing.
+// Do not use this on a files.upload body: it carries the raw bytes of a
+// user-uploaded file, and blind redaction would corrupt it; use signOnly
+// instead.
+func secureBody(body []byte) (out []byte, signature string, err error) {
+	redactor, err := redactorFromEnv()
+	if err != nil {
+		return nil, "", err
+	}
+	if redactor != nil {
+		body = redactor.Redact(body)
+	}
+
+	return signOnly(body)
+}
+
+// signOnly signs body with SLACK_SIGNING_SECRET, if set, without redacting
+// it first. Used for the files.upload body, whose bytes are a user-uploaded
+// file rather than a constructed JSON message.
+func signOnly(body []byte) (out []byte, signature string, err error) {
+	if secret := os.Getenv(EnvSlackSigningSecret); secret != "" {
+		signature = secure.Sign(secret, body)
+	}
+
+	return body, signature, nil
+}
+
+// setSignatureHeaders attaches signature (if non-empty) to req under the
+// same header names used across both send paths.
+func setSignatureHeaders(req *http.Request, signature string) {
+	if signature == "" {
+		return
+	}
+
+	req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+	req.Header.Set("X-Slack-Signature", "v0="+signature)
+}
+
+// redactorFromEnv builds a secure.Redactor from SLACK_REDACT_PATTERNS plus
+// environment values that look like secrets, or nil if neither applies.
+func redactorFromEnv() (*secure.Redactor, error) {
+	patterns := splitLines(os.Getenv(EnvSlackRedactPatterns))
+	literals := secure.SecretEnvValues(os.Environ())
+	if len(patterns) == 0 && len(literals) == 0 {
+		return nil, nil
+	}
+
+	return secure.NewRedactor(literals, patterns)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func attemptSend(webhookURL string, body []byte, signature string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setSignatureHeaders(req, signature)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		fmt.Println(res.Status)
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(res.Body)
+	serr := &SlackError{StatusCode: res.StatusCode, Body: string(respBody)}
+	if res.StatusCode == http.StatusTooManyRequests {
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			serr.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	return serr
+}
+
+// backoff returns an exponential delay (base 500ms) for attempt, plus up
+// to 50% jitter so concurrent retries don't collide.
+func backoff(attempt int) time.Duration {
+	base := 500 * time.Millisecond * time.Duration(1<<attempt)
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+	return base + jitter
+}
+
+func envInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def
+	}
+
+	return d
+}
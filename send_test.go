@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+func TestSlackErrorTemporary(t *testing.T) {
+	cases := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+
+	for _, c := range cases {
+		err := &SlackError{StatusCode: c.status}
+		if got := err.Temporary(); got != c.want {
+			t.Errorf("SlackError{StatusCode: %d}.Temporary() = %v, want %v", c.status, got, c.want)
+		}
+	}
+}
+
+func TestSendRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvSlackMaxRetries, "5")
+
+	if err := send(server.URL, slack.Message{Text: "hi"}); err != nil {
+		t.Fatalf("send() = %v, want nil", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("server got %d requests, want 3", got)
+	}
+}
+
+func TestSendStopsOnPermanentFailure(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvSlackMaxRetries, "5")
+
+	var serr *SlackError
+	if err := send(server.URL, slack.Message{Text: "hi"}); !errors.As(err, &serr) {
+		t.Fatalf("send() = %v, want *SlackError", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("server got %d requests, want 1 (no retry on permanent failure)", got)
+	}
+}
+
+func TestSendHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvSlackMaxRetries, "5")
+
+	start := time.Now()
+	if err := send(server.URL, slack.Message{Text: "hi"}); err != nil {
+		t.Fatalf("send() = %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("send() returned after %s, want it to honor the 1s Retry-After", elapsed)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("server got %d requests, want 2", got)
+	}
+}
+
+// roundTripFunc lets a plain function satisfy http.RoundTripper, so tests
+// can inject canned responses into httpClient without a real listener.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCallWebAPIJSONRetriesOnRateLimit(t *testing.T) {
+	orig := httpClient.Transport
+	defer func() { httpClient.Transport = orig }()
+
+	var calls int32
+	httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"ok":true,"ts":"123.456","channel":"C1"}`)),
+		}, nil
+	})
+
+	t.Setenv(EnvSlackMaxRetries, "5")
+
+	res, err := callWebAPIJSON("xoxb-fake", "chat.postMessage", postMessagePayload{Channel: "C1"})
+	if err != nil {
+		t.Fatalf("callWebAPIJSON() = %v, want nil", err)
+	}
+	if res.TS != "123.456" {
+		t.Fatalf("callWebAPIJSON() TS = %q, want %q", res.TS, "123.456")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("got %d requests, want 2", got)
+	}
+}
+
+func TestCallWebAPIJSONNoRetryOnAppError(t *testing.T) {
+	orig := httpClient.Transport
+	defer func() { httpClient.Transport = orig }()
+
+	var calls int32
+	httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"ok":false,"error":"invalid_auth"}`)),
+		}, nil
+	})
+
+	t.Setenv(EnvSlackMaxRetries, "5")
+
+	if _, err := callWebAPIJSON("xoxb-fake", "chat.postMessage", postMessagePayload{Channel: "C1"}); err == nil {
+		t.Fatal("callWebAPIJSON() = nil, want an error for ok:false")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("got %d requests, want 1 (ok:false is not retried)", got)
+	}
+}
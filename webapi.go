@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/onionyst/action-slack-notify/internal/slack"
+)
+
+const slackAPIBaseURL = "https://slack.com/api"
+
+// Slack Web API environment variables
+const (
+	// EnvSlackBotToken switches from the incoming webhook to the Slack Web
+	// API (chat.postMessage/chat.update/files.upload), authenticated as a bot.
+	EnvSlackBotToken = "SLACK_BOT_TOKEN"
+	// EnvSlackChannel is the channel ID or name to post to; required when
+	// EnvSlackBotToken is set.
+	EnvSlackChannel = "SLACK_CHANNEL"
+	// EnvSlackUpdateTS edits an existing message (chat.update) instead of
+	// posting a new one, so a "build started" message can become "build
+	// succeeded" in place.
+	EnvSlackUpdateTS = "SLACK_UPDATE_TS"
+	// EnvSlackThreadTS replies in an existing thread.
+	EnvSlackThreadTS = "SLACK_THREAD_TS"
+	// EnvSlackFilePath uploads a file (e.g. a test report) alongside the
+	// message via files.upload.
+	EnvSlackFilePath = "SLACK_FILE_PATH"
+	// EnvSlackFileComment is the optional files.upload initial_comment.
+	EnvSlackFileComment = "SLACK_FILE_INITIAL_COMMENT"
+)
+
+// EnvGitHubOutput is the file GitHub Actions steps append `key=value` lines
+// to in order to set step outputs.
+const EnvGitHubOutput = "GITHUB_OUTPUT"
+
+// webAPIResponse is the envelope every Slack Web API response is wrapped in.
+type webAPIResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	TS      string `json:"ts,omitempty"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// postMessagePayload wraps slack.Message with the fields chat.postMessage
+// and chat.update require that an incoming webhook instead gets implicitly
+// from its URL.
+type postMessagePayload struct {
+	slack.Message
+	Channel string `json:"channel"`
+	TS      string `json:"ts,omitempty"`
+}
+
+// sendWebAPI posts payload via the Slack Web API instead of an incoming
+// webhook: chat.postMessage by default, chat.update when SLACK_UPDATE_TS is
+// set, followed by a files.upload when SLACK_FILE_PATH is set. It returns
+// the message timestamp and channel ID for GITHUB_OUTPUT.
+func sendWebAPI(token, channel string, payload slack.Message) (ts string, channelID string, err error) {
+	payload.ThreadTS = os.Getenv(EnvSlackThreadTS)
+
+	method := "chat.postMessage"
+	body := postMessagePayload{Message: payload, Channel: channel}
+	if updateTS := os.Getenv(EnvSlackUpdateTS); updateTS != "" {
+		method = "chat.update"
+		body.TS = updateTS
+	}
+
+	res, err := callWebAPIJSON(token, method, body)
+	if err != nil {
+		return "", "", err
+	}
+
+	if filePath := os.Getenv(EnvSlackFilePath); filePath != "" {
+		if err := uploadFile(token, channel, res.TS, filePath, os.Getenv(EnvSlackFileComment)); err != nil {
+			return res.TS, res.Channel, err
+		}
+	}
+
+	return res.TS, res.Channel, nil
+}
+
+func callWebAPIJSON(token, method string, body any) (*webAPIResponse, error) {
+	enc, err := jsonMarshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, signature, err := secureBody(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := envDuration(EnvSlackTimeout, 10*time.Second)
+
+	return withRetry(func() (*webAPIResponse, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+"/"+method, bytes.NewReader(enc))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Authorization", "Bearer "+token)
+		setSignatureHeaders(req, signature)
+
+		return doWebAPIRequest(req)
+	})
+}
+
+// doWebAPIRequest sends req and decodes the webAPIResponse envelope. A
+// non-200 status (e.g. 429 rate-limiting, a 5xx outage) is reported as a
+// *SlackError so withRetry can tell it apart from a permanent ok:false
+// application error (bad token, invalid channel), which is not retried.
+func doWebAPIRequest(req *http.Request) (*webAPIResponse, error) {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		serr := &SlackError{StatusCode: res.StatusCode, Body: string(raw)}
+		if res.StatusCode == http.StatusTooManyRequests {
+			if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+				serr.RetryAfter = time.Duration(secs) * time.Second
+			}
+		}
+		return nil, serr
+	}
+
+	var out webAPIResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decoding %s response: %w", req.URL.Path, err)
+	}
+	if !out.OK {
+		return nil, fmt.Errorf("%s failed: %s", req.URL.Path, out.Error)
+	}
+
+	return &out, nil
+}
+
+// uploadFile attaches the file at path to channel via files.upload,
+// threaded under threadTS so it lands alongside the message just sent.
+func uploadFile(token, channel, threadTS, path, comment string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	if err := w.WriteField("channels", channel); err != nil {
+		return err
+	}
+	if threadTS != "" {
+		if err := w.WriteField("thread_ts", threadTS); err != nil {
+			return err
+		}
+	}
+	if comment != "" {
+		if err := w.WriteField("initial_comment", comment); err != nil {
+			return err
+		}
+	}
+
+	part, err := w.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	// signOnly, not secureBody: buf carries the raw bytes of the uploaded
+	// file, and Redact's blind substring/regex replacement would corrupt it.
+	body, signature, err := signOnly(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	timeout := envDuration(EnvSlackTimeout, 10*time.Second)
+
+	_, err = withRetry(func() (*webAPIResponse, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+"/files.upload", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		setSignatureHeaders(req, signature)
+
+		return doWebAPIRequest(req)
+	})
+	return err
+}
+
+// writeGithubOutput appends a key=value line to GITHUB_OUTPUT, the
+// mechanism GitHub Actions steps use to set outputs. A no-op if
+// GITHUB_OUTPUT isn't set (e.g. running outside Actions).
+func writeGithubOutput(key, value string) error {
+	path := os.Getenv(EnvGitHubOutput)
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s=%s\n", key, value)
+	return err
+}